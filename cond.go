@@ -0,0 +1,113 @@
+package sql
+
+import "strings"
+
+// Cond is a composable SQL condition. Implementations render themselves to
+// a WHERE-clause fragment plus the positional arguments it binds, so callers
+// never hand-concatenate SQL strings.
+type Cond interface {
+	ToSQL() (string, []interface{})
+}
+
+type condFunc struct {
+	sql  string
+	args []interface{}
+}
+
+func (c *condFunc) ToSQL() (string, []interface{}) {
+	return c.sql, c.args
+}
+
+// Eq builds "column = ?".
+func Eq(column string, value interface{}) Cond {
+	return &condFunc{sql: column + " = ?", args: []interface{}{value}}
+}
+
+// Neq builds "column <> ?".
+func Neq(column string, value interface{}) Cond {
+	return &condFunc{sql: column + " <> ?", args: []interface{}{value}}
+}
+
+// Like builds "column LIKE ?".
+func Like(column string, pattern string) Cond {
+	return &condFunc{sql: column + " LIKE ?", args: []interface{}{pattern}}
+}
+
+// IsNull builds "column IS NULL".
+func IsNull(column string) Cond {
+	return &condFunc{sql: column + " IS NULL"}
+}
+
+// IsNotNull builds "column IS NOT NULL".
+func IsNotNull(column string) Cond {
+	return &condFunc{sql: column + " IS NOT NULL"}
+}
+
+// In builds "column IN (?, ?, ...)". An empty values list renders a
+// condition that never matches, mirroring an empty SQL IN clause.
+func In(column string, values ...interface{}) Cond {
+	if len(values) == 0 {
+		return &condFunc{sql: "1 = 0"}
+	}
+	return &condFunc{
+		sql:  column + " IN (" + strings.Repeat("?, ", len(values)-1) + "?)",
+		args: values,
+	}
+}
+
+// NotIn builds "column NOT IN (?, ?, ...)". An empty values list renders a
+// condition that always matches.
+func NotIn(column string, values ...interface{}) Cond {
+	if len(values) == 0 {
+		return &condFunc{sql: "1 = 1"}
+	}
+	return &condFunc{
+		sql:  column + " NOT IN (" + strings.Repeat("?, ", len(values)-1) + "?)",
+		args: values,
+	}
+}
+
+// Between builds "column BETWEEN ? AND ?".
+func Between(column string, lo, hi interface{}) Cond {
+	return &condFunc{sql: column + " BETWEEN ? AND ?", args: []interface{}{lo, hi}}
+}
+
+type condOp struct {
+	op    string
+	conds []Cond
+}
+
+func (c *condOp) ToSQL() (string, []interface{}) {
+	parts := make([]string, 0, len(c.conds))
+	var args []interface{}
+	for _, cond := range c.conds {
+		s, a := cond.ToSQL()
+		parts = append(parts, "("+s+")")
+		args = append(args, a...)
+	}
+	return strings.Join(parts, " "+c.op+" "), args
+}
+
+// And joins conds with AND, parenthesizing each.
+func And(conds ...Cond) Cond {
+	return &condOp{op: "AND", conds: conds}
+}
+
+// Or joins conds with OR, parenthesizing each.
+func Or(conds ...Cond) Cond {
+	return &condOp{op: "OR", conds: conds}
+}
+
+type notCond struct {
+	c Cond
+}
+
+func (c *notCond) ToSQL() (string, []interface{}) {
+	s, a := c.c.ToSQL()
+	return "NOT (" + s + ")", a
+}
+
+// Not negates cond.
+func Not(cond Cond) Cond {
+	return &notCond{c: cond}
+}
@@ -0,0 +1,57 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCondToSQL(t *testing.T) {
+	cases := []struct {
+		name     string
+		cond     Cond
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{"eq", Eq("id", 1), "id = ?", []interface{}{1}},
+		{"neq", Neq("id", 1), "id <> ?", []interface{}{1}},
+		{"like", Like("name", "%foo%"), "name LIKE ?", []interface{}{"%foo%"}},
+		{"is_null", IsNull("deleted_at"), "deleted_at IS NULL", nil},
+		{"is_not_null", IsNotNull("deleted_at"), "deleted_at IS NOT NULL", nil},
+		{"in", In("id", 1, 2, 3), "id IN (?, ?, ?)", []interface{}{1, 2, 3}},
+		{"in_empty", In("id"), "1 = 0", nil},
+		{"not_in", NotIn("id", 1, 2), "id NOT IN (?, ?)", []interface{}{1, 2}},
+		{"not_in_empty", NotIn("id"), "1 = 1", nil},
+		{"between", Between("created_at", 1, 2), "created_at BETWEEN ? AND ?", []interface{}{1, 2}},
+		{
+			"and",
+			And(Eq("a", 1), Eq("b", 2)),
+			"(a = ?) AND (b = ?)",
+			[]interface{}{1, 2},
+		},
+		{
+			"or",
+			Or(Eq("a", 1), Eq("b", 2)),
+			"(a = ?) OR (b = ?)",
+			[]interface{}{1, 2},
+		},
+		{"not", Not(Eq("a", 1)), "NOT (a = ?)", []interface{}{1}},
+		{
+			"nested",
+			And(Eq("a", 1), Or(Eq("b", 2), Eq("c", 3))),
+			"(a = ?) AND ((b = ?) OR (c = ?))",
+			[]interface{}{1, 2, 3},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sql, args := c.cond.ToSQL()
+			if sql != c.wantSQL {
+				t.Errorf("ToSQL() sql = %q, want %q", sql, c.wantSQL)
+			}
+			if !reflect.DeepEqual(args, c.wantArgs) {
+				t.Errorf("ToSQL() args = %v, want %v", args, c.wantArgs)
+			}
+		})
+	}
+}
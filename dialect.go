@@ -0,0 +1,165 @@
+package sql
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// Dialect isolates the SQL differences between drivers: placeholder
+// syntax, identifier quoting, upsert clauses, and how an inserted row's
+// auto-increment value is recovered. Table routes all of that through the
+// Dialect for its driverName instead of hardcoding mysql/sqlite3 behavior.
+type Dialect interface {
+	// Name is the driver name this Dialect was built for ("mysql",
+	// "sqlite3", "postgres").
+	Name() string
+
+	// Placeholder renders the i-th (1-based) bind parameter.
+	Placeholder(i int) string
+
+	// Quote renders ident as a quoted identifier.
+	Quote(ident string) string
+
+	// UpsertClause renders the clause appended to an INSERT to turn it
+	// into an upsert keyed by pkCols, updating updateCols on conflict.
+	UpsertClause(pkCols, updateCols []string) string
+
+	// SupportsReturning reports whether this dialect can recover an
+	// inserted row's auto-increment value via a RETURNING clause instead
+	// of sql.Result.LastInsertId.
+	SupportsReturning() bool
+
+	// LastInsertID recovers the auto-increment value of a just-inserted
+	// row from res, which aiCol names.
+	LastInsertID(res sql.Result, aiCol string) (int64, error)
+}
+
+func dialectForDriver(driverName string) Dialect {
+	switch driverName {
+	case "mysql":
+		return mysqlDialect{}
+	case "sqlite3":
+		return sqlite3Dialect{}
+	case "postgres":
+		return postgresDialect{}
+	default:
+		panic("unsupported driver: " + driverName)
+	}
+}
+
+// DialectFor returns the Dialect for driverName ("mysql", "sqlite3",
+// "postgres"), for packages outside sql that need to render driver-aware
+// SQL of their own, such as sql/migrate.
+func DialectFor(driverName string) Dialect {
+	return dialectForDriver(driverName)
+}
+
+// bindPlaceholders rewrites the "?"-style placeholders Cond and hand-written
+// WHERE clauses use into dialect's own placeholder syntax.
+func bindPlaceholders(dialect Dialect, query string) string {
+	if dialect.Placeholder(1) == "?" || !strings.ContainsRune(query, '?') {
+		return query
+	}
+
+	var buf strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			buf.WriteString(dialect.Placeholder(n))
+		} else {
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) Placeholder(int) string { return "?" }
+
+func (mysqlDialect) Quote(ident string) string { return "`" + ident + "`" }
+
+func (mysqlDialect) UpsertClause(_, updateCols []string) string {
+	var buf strings.Builder
+	buf.WriteString("ON DUPLICATE KEY UPDATE ")
+	for i, c := range updateCols {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(c)
+		buf.WriteString(" = VALUES(")
+		buf.WriteString(c)
+		buf.WriteString(")")
+	}
+	return buf.String()
+}
+
+func (mysqlDialect) SupportsReturning() bool { return false }
+
+func (mysqlDialect) LastInsertID(res sql.Result, _ string) (int64, error) {
+	return res.LastInsertId()
+}
+
+type sqlite3Dialect struct{}
+
+func (sqlite3Dialect) Name() string { return "sqlite3" }
+
+func (sqlite3Dialect) Placeholder(int) string { return "?" }
+
+func (sqlite3Dialect) Quote(ident string) string { return `"` + ident + `"` }
+
+func (sqlite3Dialect) UpsertClause(pkCols, updateCols []string) string {
+	var buf strings.Builder
+	buf.WriteString("ON CONFLICT(")
+	buf.WriteString(strings.Join(pkCols, ", "))
+	buf.WriteString(") DO UPDATE SET ")
+	for i, c := range updateCols {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(c)
+		buf.WriteString(" = excluded.")
+		buf.WriteString(c)
+	}
+	return buf.String()
+}
+
+func (sqlite3Dialect) SupportsReturning() bool { return false }
+
+func (sqlite3Dialect) LastInsertID(res sql.Result, _ string) (int64, error) {
+	return res.LastInsertId()
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) Placeholder(i int) string { return "$" + strconv.Itoa(i) }
+
+func (postgresDialect) Quote(ident string) string { return `"` + ident + `"` }
+
+func (postgresDialect) UpsertClause(pkCols, updateCols []string) string {
+	var buf strings.Builder
+	buf.WriteString("ON CONFLICT(")
+	buf.WriteString(strings.Join(pkCols, ", "))
+	buf.WriteString(") DO UPDATE SET ")
+	for i, c := range updateCols {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(c)
+		buf.WriteString(" = EXCLUDED.")
+		buf.WriteString(c)
+	}
+	return buf.String()
+}
+
+func (postgresDialect) SupportsReturning() bool { return true }
+
+func (postgresDialect) LastInsertID(sql.Result, string) (int64, error) {
+	panic("postgres recovers the auto-increment value via RETURNING, not LastInsertId")
+}
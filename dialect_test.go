@@ -0,0 +1,109 @@
+package sql
+
+import "testing"
+
+type fakeResult struct {
+	lastInsertID int64
+	err          error
+}
+
+func (r fakeResult) LastInsertId() (int64, error) { return r.lastInsertID, r.err }
+func (r fakeResult) RowsAffected() (int64, error) { return 0, nil }
+
+func TestDialectForDriver(t *testing.T) {
+	cases := []struct {
+		driverName string
+		wantName   string
+	}{
+		{"mysql", "mysql"},
+		{"sqlite3", "sqlite3"},
+		{"postgres", "postgres"},
+	}
+	for _, c := range cases {
+		d := DialectFor(c.driverName)
+		if d.Name() != c.wantName {
+			t.Errorf("DialectFor(%q).Name() = %q, want %q", c.driverName, d.Name(), c.wantName)
+		}
+	}
+}
+
+func TestDialectForDriverPanicsOnUnknown(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("DialectFor with an unknown driver should panic")
+		}
+	}()
+	DialectFor("unknown")
+}
+
+func TestMysqlDialect(t *testing.T) {
+	d := mysqlDialect{}
+	if got := d.Placeholder(1); got != "?" {
+		t.Errorf("Placeholder(1) = %q, want %q", got, "?")
+	}
+	if got := d.Quote("id"); got != "`id`" {
+		t.Errorf("Quote(%q) = %q, want %q", "id", got, "`id`")
+	}
+	if got := d.UpsertClause([]string{"id"}, []string{"name", "age"}); got != "ON DUPLICATE KEY UPDATE name = VALUES(name), age = VALUES(age)" {
+		t.Errorf("UpsertClause() = %q", got)
+	}
+	if d.SupportsReturning() {
+		t.Error("mysqlDialect should not support RETURNING")
+	}
+	id, err := d.LastInsertID(fakeResult{lastInsertID: 42}, "id")
+	if err != nil || id != 42 {
+		t.Errorf("LastInsertID() = (%d, %v), want (42, nil)", id, err)
+	}
+}
+
+func TestSqlite3Dialect(t *testing.T) {
+	d := sqlite3Dialect{}
+	if got := d.Placeholder(2); got != "?" {
+		t.Errorf("Placeholder(2) = %q, want %q", got, "?")
+	}
+	if got := d.Quote("id"); got != `"id"` {
+		t.Errorf("Quote(%q) = %q, want %q", "id", got, `"id"`)
+	}
+	if got := d.UpsertClause([]string{"id"}, []string{"name"}); got != "ON CONFLICT(id) DO UPDATE SET name = excluded.name" {
+		t.Errorf("UpsertClause() = %q", got)
+	}
+	if d.SupportsReturning() {
+		t.Error("sqlite3Dialect should not support RETURNING")
+	}
+}
+
+func TestPostgresDialect(t *testing.T) {
+	d := postgresDialect{}
+	if got := d.Placeholder(3); got != "$3" {
+		t.Errorf("Placeholder(3) = %q, want %q", got, "$3")
+	}
+	if got := d.Quote("id"); got != `"id"` {
+		t.Errorf("Quote(%q) = %q, want %q", "id", got, `"id"`)
+	}
+	if got := d.UpsertClause([]string{"id"}, []string{"name"}); got != "ON CONFLICT(id) DO UPDATE SET name = EXCLUDED.name" {
+		t.Errorf("UpsertClause() = %q", got)
+	}
+	if !d.SupportsReturning() {
+		t.Error("postgresDialect should support RETURNING")
+	}
+}
+
+func TestBindPlaceholders(t *testing.T) {
+	cases := []struct {
+		name    string
+		dialect Dialect
+		query   string
+		want    string
+	}{
+		{"mysql_noop", mysqlDialect{}, "id = ? AND name = ?", "id = ? AND name = ?"},
+		{"sqlite3_noop", sqlite3Dialect{}, "id = ? AND name = ?", "id = ? AND name = ?"},
+		{"postgres_rewrite", postgresDialect{}, "id = ? AND name = ?", "id = $1 AND name = $2"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := bindPlaceholders(c.dialect, c.query); got != c.want {
+				t.Errorf("bindPlaceholders() = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
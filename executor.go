@@ -0,0 +1,19 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// executor is the subset of *sql.DB/*sql.Tx that Table needs to run
+// queries. Both the context-free and context-aware forms are required so
+// Table's *Context methods can propagate cancellation/deadlines/tracing
+// all the way down.
+type executor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
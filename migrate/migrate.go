@@ -0,0 +1,176 @@
+// Package migrate is a schema-migration runner for github.com/gopub/sql
+// databases, in the spirit of xormigrate.
+package migrate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gopub/log"
+	gopubsql "github.com/gopub/sql"
+)
+
+// Migration is a single schema change. Migrate applies it; Rollback, if
+// non-nil, reverts it.
+type Migration struct {
+	ID       string
+	Migrate  func(*gopubsql.DB) error
+	Rollback func(*gopubsql.DB) error
+}
+
+// Status describes whether a Migration has been applied.
+type Status struct {
+	ID      string
+	Applied bool
+}
+
+// Migrator applies Migrations in order, recording applied IDs in a
+// schema_migrations table. It is given driverName and keeps that driver's
+// Dialect rather than asking db, the same way Table does, since driver-aware SQL (the
+// bookkeeping table's placeholders) is needed before a single query has run.
+type Migrator struct {
+	db         *gopubsql.DB
+	dialect    gopubsql.Dialect
+	migrations []*Migration
+}
+
+// NewMigrator creates a Migrator over db and ensures the schema_migrations
+// bookkeeping table exists. driverName is one of "mysql", "sqlite3",
+// "postgres", matching the driver db was opened with.
+func NewMigrator(db *gopubsql.DB, driverName string, migrations ...*Migration) *Migrator {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations(
+id VARCHAR(255) PRIMARY KEY,
+applied_at BIGINT NOT NULL
+)`)
+	if err != nil {
+		log.Fatalf("create schema_migrations: %v", err)
+	}
+	return &Migrator{db: db, dialect: gopubsql.DialectFor(driverName), migrations: migrations}
+}
+
+// Migrate applies all pending migrations in order.
+func (m *Migrator) Migrate() error {
+	applied, err := m.appliedIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, mig := range m.migrations {
+		if applied[mig.ID] {
+			continue
+		}
+		if err := m.apply(mig); err != nil {
+			return fmt.Errorf("migrate %s: %w", mig.ID, err)
+		}
+		log.Infof("applied migration %s", mig.ID)
+	}
+	return nil
+}
+
+// RollbackLast reverts the most recently applied migration.
+func (m *Migrator) RollbackLast() error {
+	applied, err := m.appliedIDs()
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		if mig := m.migrations[i]; applied[mig.ID] {
+			return m.revert(mig)
+		}
+	}
+	return nil
+}
+
+// RollbackTo reverts every applied migration after id, most-recent first.
+func (m *Migrator) RollbackTo(id string) error {
+	applied, err := m.appliedIDs()
+	if err != nil {
+		return err
+	}
+
+	target := -1
+	for i, mig := range m.migrations {
+		if mig.ID == id {
+			target = i
+			break
+		}
+	}
+	if target < 0 {
+		return fmt.Errorf("migrate: unknown migration id %q", id)
+	}
+
+	for i := len(m.migrations) - 1; i > target; i-- {
+		if mig := m.migrations[i]; applied[mig.ID] {
+			if err := m.revert(mig); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Status reports, for every registered migration in order, whether it has
+// been applied.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.appliedIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, mig := range m.migrations {
+		statuses[i] = Status{ID: mig.ID, Applied: applied[mig.ID]}
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) appliedIDs() (map[string]bool, error) {
+	rows, err := m.db.Query("SELECT id FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		applied[id] = true
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) apply(mig *Migration) error {
+	if err := mig.Migrate(m.db); err != nil {
+		return err
+	}
+	_, err := m.db.Exec(insertMigrationQuery(m.dialect), mig.ID, time.Now().Unix())
+	return err
+}
+
+func (m *Migrator) revert(mig *Migration) error {
+	if mig.Rollback == nil {
+		return fmt.Errorf("migrate: %s has no Rollback func", mig.ID)
+	}
+
+	if err := mig.Rollback(m.db); err != nil {
+		return fmt.Errorf("rollback %s: %w", mig.ID, err)
+	}
+	if _, err := m.db.Exec(deleteMigrationQuery(m.dialect), mig.ID); err != nil {
+		return fmt.Errorf("rollback %s: %w", mig.ID, err)
+	}
+	log.Infof("rolled back migration %s", mig.ID)
+	return nil
+}
+
+func insertMigrationQuery(dialect gopubsql.Dialect) string {
+	return fmt.Sprintf("INSERT INTO schema_migrations(id, applied_at) VALUES(%s, %s)",
+		dialect.Placeholder(1), dialect.Placeholder(2))
+}
+
+func deleteMigrationQuery(dialect gopubsql.Dialect) string {
+	return fmt.Sprintf("DELETE FROM schema_migrations WHERE id = %s", dialect.Placeholder(1))
+}
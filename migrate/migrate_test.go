@@ -0,0 +1,33 @@
+package migrate
+
+import (
+	"testing"
+
+	gopubsql "github.com/gopub/sql"
+)
+
+func TestInsertMigrationQuery(t *testing.T) {
+	cases := map[string]string{
+		"mysql":    "INSERT INTO schema_migrations(id, applied_at) VALUES(?, ?)",
+		"sqlite3":  "INSERT INTO schema_migrations(id, applied_at) VALUES(?, ?)",
+		"postgres": "INSERT INTO schema_migrations(id, applied_at) VALUES($1, $2)",
+	}
+	for driverName, want := range cases {
+		if got := insertMigrationQuery(gopubsql.DialectFor(driverName)); got != want {
+			t.Errorf("insertMigrationQuery(%q) = %q, want %q", driverName, got, want)
+		}
+	}
+}
+
+func TestDeleteMigrationQuery(t *testing.T) {
+	cases := map[string]string{
+		"mysql":    "DELETE FROM schema_migrations WHERE id = ?",
+		"sqlite3":  "DELETE FROM schema_migrations WHERE id = ?",
+		"postgres": "DELETE FROM schema_migrations WHERE id = $1",
+	}
+	for driverName, want := range cases {
+		if got := deleteMigrationQuery(gopubsql.DialectFor(driverName)); got != want {
+			t.Errorf("deleteMigrationQuery(%q) = %q, want %q", driverName, got, want)
+		}
+	}
+}
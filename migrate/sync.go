@@ -0,0 +1,186 @@
+package migrate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	gopubsql "github.com/gopub/sql"
+)
+
+// Sync inspects each record's tagged struct fields (the same metadata Table
+// relies on) and brings its table in line: a missing table is created, and
+// missing columns are added. It never drops or alters existing columns,
+// mirroring xorm's Sync2. driverName is one of "mysql", "sqlite3",
+// "postgres", matching the driver db was opened with.
+func Sync(db *gopubsql.DB, driverName string, records ...interface{}) error {
+	for _, record := range records {
+		if err := syncOne(db, driverName, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func syncOne(db *gopubsql.DB, driverName string, record interface{}) error {
+	table := gopubsql.TableName(record)
+	columns := gopubsql.Columns(reflect.TypeOf(record))
+
+	existing, err := existingColumns(db, driverName, table)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		query := createTableQuery(driverName, table, columns)
+		_, err := db.Exec(query)
+		return err
+	}
+
+	for _, c := range columns {
+		if existing[c.Name] {
+			continue
+		}
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, columnDef(driverName, c, false))
+		if _, err := db.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// existingColumns returns the set of column names already present in
+// table, or nil if the table doesn't exist yet.
+func existingColumns(db *gopubsql.DB, driverName, table string) (map[string]bool, error) {
+	var query string
+	var args []interface{}
+	switch driverName {
+	case "sqlite3":
+		query = fmt.Sprintf("PRAGMA table_info(%s)", table)
+	case "postgres":
+		query = "SELECT column_name FROM information_schema.columns WHERE table_name = $1"
+		args = []interface{}{table}
+	default: // mysql
+		query = "SELECT column_name FROM information_schema.columns WHERE table_name = ?"
+		args = []interface{}{table}
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if driverName == "sqlite3" {
+			var cid int
+			var colType string
+			var notNull, pk int
+			var dflt interface{}
+			if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+				return nil, err
+			}
+		} else {
+			if err := rows.Scan(&name); err != nil {
+				return nil, err
+			}
+		}
+		columns[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, nil
+	}
+	return columns, nil
+}
+
+func createTableQuery(driverName, table string, columns []gopubsql.ColumnDef) string {
+	var pkCols []string
+	for _, c := range columns {
+		if c.PrimaryKey && !c.AutoIncr {
+			pkCols = append(pkCols, c.Name)
+		}
+	}
+	// A composite key needs one table-level PRIMARY KEY(...) constraint;
+	// decorating each column individually is rejected by postgres/mysql.
+	composite := len(pkCols) > 1
+
+	defs := make([]string, len(columns))
+	for i, c := range columns {
+		defs[i] = columnDef(driverName, c, composite)
+	}
+	if composite {
+		defs = append(defs, "PRIMARY KEY ("+strings.Join(pkCols, ", ")+")")
+	}
+	return fmt.Sprintf("CREATE TABLE %s (%s)", table, strings.Join(defs, ", "))
+}
+
+func columnDef(driverName string, c gopubsql.ColumnDef, compositePK bool) string {
+	if c.AutoIncr {
+		switch driverName {
+		case "postgres":
+			return c.Name + " SERIAL PRIMARY KEY"
+		case "mysql":
+			return c.Name + " INT AUTO_INCREMENT PRIMARY KEY"
+		default: // sqlite3
+			return c.Name + " INTEGER PRIMARY KEY AUTOINCREMENT"
+		}
+	}
+
+	def := c.Name + " " + sqlType(driverName, c.GoType)
+	if c.PrimaryKey && !compositePK {
+		def += " PRIMARY KEY"
+	}
+	return def
+}
+
+func sqlType(driverName string, typ reflect.Type) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch driverName {
+	case "postgres":
+		switch typ.Kind() {
+		case reflect.Bool:
+			return "BOOLEAN"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			return "INTEGER"
+		case reflect.Int64, reflect.Uint64:
+			return "BIGINT"
+		case reflect.Float32, reflect.Float64:
+			return "DOUBLE PRECISION"
+		default:
+			return "TEXT"
+		}
+	case "mysql":
+		switch typ.Kind() {
+		case reflect.Bool:
+			return "TINYINT(1)"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			return "INT"
+		case reflect.Int64, reflect.Uint64:
+			return "BIGINT"
+		case reflect.Float32, reflect.Float64:
+			return "DOUBLE"
+		default:
+			return "VARCHAR(255)"
+		}
+	default: // sqlite3
+		switch typ.Kind() {
+		case reflect.Bool:
+			return "BOOLEAN"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return "INTEGER"
+		case reflect.Float32, reflect.Float64:
+			return "REAL"
+		default:
+			return "TEXT"
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package migrate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	gopubsql "github.com/gopub/sql"
+)
+
+func TestCreateTableQuerySinglePK(t *testing.T) {
+	columns := []gopubsql.ColumnDef{
+		{Name: "id", GoType: reflect.TypeOf(int64(0)), PrimaryKey: true, AutoIncr: true},
+		{Name: "name", GoType: reflect.TypeOf("")},
+	}
+
+	cases := map[string]string{
+		"sqlite3":  "CREATE TABLE t (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)",
+		"mysql":    "CREATE TABLE t (id INT AUTO_INCREMENT PRIMARY KEY, name VARCHAR(255))",
+		"postgres": "CREATE TABLE t (id SERIAL PRIMARY KEY, name TEXT)",
+	}
+	for driverName, want := range cases {
+		if got := createTableQuery(driverName, "t", columns); got != want {
+			t.Errorf("createTableQuery(%q) = %q, want %q", driverName, got, want)
+		}
+	}
+}
+
+func TestCreateTableQueryCompositePK(t *testing.T) {
+	columns := []gopubsql.ColumnDef{
+		{Name: "a", GoType: reflect.TypeOf(int64(0)), PrimaryKey: true},
+		{Name: "b", GoType: reflect.TypeOf(int64(0)), PrimaryKey: true},
+		{Name: "c", GoType: reflect.TypeOf("")},
+	}
+
+	cases := map[string]string{
+		"sqlite3":  "CREATE TABLE t (a INTEGER, b INTEGER, c TEXT, PRIMARY KEY (a, b))",
+		"mysql":    "CREATE TABLE t (a BIGINT, b BIGINT, c VARCHAR(255), PRIMARY KEY (a, b))",
+		"postgres": "CREATE TABLE t (a BIGINT, b BIGINT, c TEXT, PRIMARY KEY (a, b))",
+	}
+	for driverName, want := range cases {
+		got := createTableQuery(driverName, "t", columns)
+		if got != want {
+			t.Errorf("createTableQuery(%q) = %q, want %q", driverName, got, want)
+		}
+		if strings.Count(got, "PRIMARY KEY") != 1 {
+			t.Errorf("createTableQuery(%q) = %q, want exactly one PRIMARY KEY clause", driverName, got)
+		}
+	}
+}
+
+func TestColumnDef(t *testing.T) {
+	c := gopubsql.ColumnDef{Name: "id", GoType: reflect.TypeOf(int64(0)), PrimaryKey: true}
+
+	if got := columnDef("sqlite3", c, false); got != "id INTEGER PRIMARY KEY" {
+		t.Errorf("columnDef(single) = %q", got)
+	}
+	if got := columnDef("sqlite3", c, true); got != "id INTEGER" {
+		t.Errorf("columnDef(composite) = %q, want no inline PRIMARY KEY", got)
+	}
+}
@@ -0,0 +1,157 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+)
+
+// Query is a chainable builder for SELECT/COUNT/DELETE conditions. Start one
+// with Table.Where and terminate it with Find, One, Count, or Remove. Each
+// terminator has a *Context sibling, e.g. FindContext, that threads a
+// context.Context through to the underlying Table call; the plain form
+// just calls it with context.Background().
+type Query struct {
+	t       *Table
+	cond    Cond
+	orderBy string
+	limit   int
+	offset  int
+}
+
+// Where starts a Query rooted at cond.
+func (t *Table) Where(cond Cond) *Query {
+	return &Query{t: t, cond: cond}
+}
+
+// And narrows the query by ANDing cond onto the existing condition.
+func (q *Query) And(cond Cond) *Query {
+	if q.cond == nil {
+		q.cond = cond
+	} else {
+		q.cond = And(q.cond, cond)
+	}
+	return q
+}
+
+// Or widens the query by ORing cond onto the existing condition.
+func (q *Query) Or(cond Cond) *Query {
+	if q.cond == nil {
+		q.cond = cond
+	} else {
+		q.cond = Or(q.cond, cond)
+	}
+	return q
+}
+
+// In is shorthand for And(In(column, values...)).
+func (q *Query) In(column string, values ...interface{}) *Query {
+	return q.And(In(column, values...))
+}
+
+// NotIn is shorthand for And(NotIn(column, values...)).
+func (q *Query) NotIn(column string, values ...interface{}) *Query {
+	return q.And(NotIn(column, values...))
+}
+
+// Between is shorthand for And(Between(column, lo, hi)).
+func (q *Query) Between(column string, lo, hi interface{}) *Query {
+	return q.And(Between(column, lo, hi))
+}
+
+// IsNull is shorthand for And(IsNull(column)).
+func (q *Query) IsNull(column string) *Query {
+	return q.And(IsNull(column))
+}
+
+// OrderBy sets the ORDER BY clause, e.g. "id DESC".
+func (q *Query) OrderBy(orderBy string) *Query {
+	q.orderBy = orderBy
+	return q
+}
+
+// Limit sets the LIMIT clause. Values <= 0 are ignored.
+func (q *Query) Limit(limit int) *Query {
+	q.limit = limit
+	return q
+}
+
+// Offset sets the OFFSET clause. Values <= 0 are ignored.
+func (q *Query) Offset(offset int) *Query {
+	q.offset = offset
+	return q
+}
+
+// where renders the condition plus ORDER BY/LIMIT/OFFSET into a fragment
+// suitable for Table.Select/SelectOne, which already prepend " WHERE ". If
+// there's no condition but ORDER BY/LIMIT/OFFSET is set, it falls back to
+// the "1 = 1" tautology (the same one NotIn uses for an empty list) so the
+// fragment is still valid right after WHERE.
+func (q *Query) where() (string, []interface{}) {
+	var where string
+	var args []interface{}
+	if q.cond != nil {
+		where, args = q.cond.ToSQL()
+	} else if len(q.orderBy) > 0 || q.limit > 0 || q.offset > 0 {
+		where = "1 = 1"
+	}
+	if len(q.orderBy) > 0 {
+		where += " ORDER BY " + q.orderBy
+	}
+	if q.limit > 0 {
+		where += fmt.Sprintf(" LIMIT %d", q.limit)
+	}
+	if q.offset > 0 {
+		where += fmt.Sprintf(" OFFSET %d", q.offset)
+	}
+	return where, args
+}
+
+// Find runs the query and scans all matching rows into records, a pointer
+// to a slice, following Table.Select's conventions.
+func (q *Query) Find(records interface{}) error {
+	return q.FindContext(context.Background(), records)
+}
+
+func (q *Query) FindContext(ctx context.Context, records interface{}) error {
+	where, args := q.where()
+	return q.t.SelectContext(ctx, records, where, args...)
+}
+
+// One runs the query and scans the first matching row into record,
+// following Table.SelectOne's conventions.
+func (q *Query) One(record interface{}) error {
+	return q.OneContext(context.Background(), record)
+}
+
+func (q *Query) OneContext(ctx context.Context, record interface{}) error {
+	where, args := q.where()
+	return q.t.SelectOneContext(ctx, record, where, args...)
+}
+
+// Count returns the number of rows matching the query's condition. ORDER
+// BY/LIMIT/OFFSET are ignored since they don't affect the row count.
+func (q *Query) Count() (int, error) {
+	return q.CountContext(context.Background())
+}
+
+func (q *Query) CountContext(ctx context.Context) (int, error) {
+	var where string
+	var args []interface{}
+	if q.cond != nil {
+		where, args = q.cond.ToSQL()
+	}
+	return q.t.CountContext(ctx, where, args...)
+}
+
+// Remove deletes all rows matching the query's condition.
+func (q *Query) Remove() error {
+	return q.RemoveContext(context.Background())
+}
+
+func (q *Query) RemoveContext(ctx context.Context) error {
+	if q.cond == nil {
+		panic("where is empty")
+	}
+	where, args := q.cond.ToSQL()
+	return q.t.DeleteContext(ctx, where, args...)
+}
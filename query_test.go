@@ -0,0 +1,85 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryWhere(t *testing.T) {
+	cases := []struct {
+		name     string
+		build    func() *Query
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			"cond_only",
+			func() *Query { return (&Query{}).And(Eq("id", 1)) },
+			"id = ?",
+			[]interface{}{1},
+		},
+		{
+			"and_or",
+			func() *Query { return (&Query{}).And(Eq("a", 1)).Or(Eq("b", 2)) },
+			"(a = ?) OR (b = ?)",
+			[]interface{}{1, 2},
+		},
+		{
+			"order_limit_offset",
+			func() *Query {
+				return (&Query{}).And(Eq("id", 1)).OrderBy("id DESC").Limit(20).Offset(40)
+			},
+			"id = ? ORDER BY id DESC LIMIT 20 OFFSET 40",
+			[]interface{}{1},
+		},
+		{
+			"in_between",
+			func() *Query {
+				return (&Query{}).In("id", 1, 2).Between("created_at", 3, 4)
+			},
+			"(id IN (?, ?)) AND (created_at BETWEEN ? AND ?)",
+			[]interface{}{1, 2, 3, 4},
+		},
+		{
+			"no_cond_with_limit",
+			func() *Query { return (&Query{}).Limit(10) },
+			"1 = 1 LIMIT 10",
+			nil,
+		},
+		{
+			"no_cond_no_modifiers",
+			func() *Query { return &Query{} },
+			"",
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q := c.build()
+			where, args := q.where()
+			if where != c.wantSQL {
+				t.Errorf("where() sql = %q, want %q", where, c.wantSQL)
+			}
+			if !reflect.DeepEqual(args, c.wantArgs) {
+				t.Errorf("where() args = %v, want %v", args, c.wantArgs)
+			}
+			// Table.Select/SelectOne prepend "WHERE " verbatim to a
+			// non-empty where() result, so it must never start with a space -
+			// that would leave a dangling "WHERE " with nothing before
+			// ORDER BY/LIMIT/OFFSET.
+			if len(where) > 0 && where[0] == ' ' {
+				t.Errorf("where() = %q starts with a space; %q would be invalid SQL", where, "WHERE "+where)
+			}
+		})
+	}
+}
+
+func TestQueryRemovePanicsWithoutCond(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Remove() with no condition should panic")
+		}
+	}()
+	(&Query{}).Remove()
+}
@@ -0,0 +1,191 @@
+package sql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/gopub/utils"
+)
+
+// ScanRows scans every remaining row in rows into dest, a pointer to a
+// slice of struct or pointer-to-struct elements. Result columns are
+// matched to fields by getColumnInfo's tag/snake-case rules; a column with
+// no matching field (e.g. one pulled in by a JOIN) is read and discarded,
+// and a field with no matching column is left at its zero value. This
+// lets callers hand-write SELECTs, including JOINs and column subsets,
+// without losing Table's JSON/nullable handling. rows is left open;
+// closing it remains the caller's responsibility.
+func ScanRows(rows *sql.Rows, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr {
+		panic("must be a pointer to slice")
+	}
+
+	sliceType := v.Type().Elem()
+	if sliceType.Kind() != reflect.Slice {
+		panic("must be a pointer to slice")
+	}
+
+	isPtr := false
+	elemType := sliceType.Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+		isPtr = true
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		panic("slice element must be a struct or pointer to struct")
+	}
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	info := getColumnInfo(elemType)
+
+	if v.IsNil() {
+		v.Set(reflect.New(sliceType))
+	}
+	sliceValue := v.Elem()
+	dests := make([]interface{}, len(cols))
+
+	for rows.Next() {
+		ptrToElem := utils.DeepNew(elemType)
+		elem := ptrToElem.Elem()
+
+		for i, name := range cols {
+			dests[i] = columnDest(info, elem, name)
+		}
+
+		if err := rows.Scan(dests...); err != nil {
+			return err
+		}
+
+		for i, name := range cols {
+			if err := applyColumnDest(info, elem, name, dests[i]); err != nil {
+				return err
+			}
+		}
+
+		if isPtr {
+			sliceValue = reflect.Append(sliceValue, ptrToElem)
+		} else {
+			sliceValue = reflect.Append(sliceValue, elem)
+		}
+	}
+	v.Elem().Set(sliceValue)
+	return rows.Err()
+}
+
+// ScanRow scans row into dest, a pointer to a struct, using the same
+// column-matching rules as ScanRows. Unlike *sql.Rows, *sql.Row exposes no
+// column names, so the query backing row must select dest's mapped
+// columns in getColumnInfo's order — the same convention the queries
+// Table.SelectOne generates already follow.
+func ScanRow(row *sql.Row, dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr {
+		panic("not pointer to a struct")
+	}
+
+	elem := rv.Elem()
+	if elem.Kind() != reflect.Struct {
+		panic("not pointer to a struct")
+	}
+
+	info := getColumnInfo(elem.Type())
+	dests := make([]interface{}, len(info.names))
+	for i, name := range info.names {
+		dests[i] = columnDest(info, elem, name)
+	}
+
+	if err := row.Scan(dests...); err != nil {
+		return err
+	}
+
+	for i, name := range info.names {
+		if err := applyColumnDest(info, elem, name, dests[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnDest returns the Scan destination for column name within elem. A
+// name outside info's mapped columns gets a destination that discards
+// whatever value is read.
+func columnDest(info *columnInfo, elem reflect.Value, name string) interface{} {
+	idx, ok := info.nameToIndex[name]
+	if !ok {
+		var discard interface{}
+		return &discard
+	}
+
+	if utils.IndexOfString(info.jsonNames, name) >= 0 {
+		var data []byte
+		return &data
+	}
+
+	if utils.IndexOfString(info.nullableNames, name) >= 0 {
+		switch elem.FieldByIndex(idx).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			var v sql.NullInt64
+			return &v
+		case reflect.Bool:
+			var b sql.NullBool
+			return &b
+		case reflect.Float32, reflect.Float64:
+			var v sql.NullFloat64
+			return &v
+		case reflect.String:
+			var v sql.NullString
+			return &v
+		default:
+			panic("invalid nullable type" + fmt.Sprint(elem.FieldByIndex(idx).Type()))
+		}
+	}
+
+	return elem.FieldByIndex(idx).Addr().Interface()
+}
+
+// applyColumnDest finishes populating elem's field for column name after
+// Scan has filled dest, unwrapping the JSON/nullable indirection columnDest
+// introduced. It's a no-op for columns with no matching field.
+func applyColumnDest(info *columnInfo, elem reflect.Value, name string, dest interface{}) error {
+	idx, ok := info.nameToIndex[name]
+	if !ok {
+		return nil
+	}
+
+	if utils.IndexOfString(info.jsonNames, name) >= 0 {
+		data := *dest.(*[]byte)
+		return json.Unmarshal(data, elem.FieldByIndex(idx).Addr().Interface())
+	}
+
+	if utils.IndexOfString(info.nullableNames, name) >= 0 {
+		switch v := reflect.ValueOf(dest).Elem().Interface().(type) {
+		case sql.NullString:
+			if v.Valid {
+				elem.FieldByIndex(idx).SetString(v.String)
+			}
+		case sql.NullFloat64:
+			if v.Valid {
+				elem.FieldByIndex(idx).SetFloat(v.Float64)
+			}
+		case sql.NullBool:
+			if v.Valid {
+				elem.FieldByIndex(idx).SetBool(v.Bool)
+			}
+		case sql.NullInt64:
+			if v.Valid {
+				elem.FieldByIndex(idx).SetInt(v.Int64)
+			}
+		default:
+			panic("invalid type:" + fmt.Sprint(v))
+		}
+	}
+	return nil
+}
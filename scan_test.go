@@ -0,0 +1,59 @@
+package sql
+
+import "testing"
+
+// ScanRows/ScanRow's real scanning logic goes through getColumnInfo, which
+// (like the rest of Table's column metadata) isn't part of this source
+// tree, so it can't be exercised here. Their input-validation panics run
+// before that call, so they're the one part of the contract this package
+// can cover on its own.
+
+func TestScanRowsPanicsOnNonPointer(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("ScanRows(non-pointer dest) should panic")
+		}
+	}()
+	var dest []struct{}
+	ScanRows(nil, dest)
+}
+
+func TestScanRowsPanicsOnNonSlice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("ScanRows(pointer to non-slice) should panic")
+		}
+	}()
+	var dest struct{}
+	ScanRows(nil, &dest)
+}
+
+func TestScanRowsPanicsOnNonStructElem(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("ScanRows(slice of non-struct) should panic")
+		}
+	}()
+	var dest []int
+	ScanRows(nil, &dest)
+}
+
+func TestScanRowPanicsOnNonPointer(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("ScanRow(non-pointer dest) should panic")
+		}
+	}()
+	var dest struct{}
+	ScanRow(nil, dest)
+}
+
+func TestScanRowPanicsOnNonStruct(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("ScanRow(pointer to non-struct) should panic")
+		}
+	}()
+	var dest int
+	ScanRow(nil, &dest)
+}
@@ -0,0 +1,44 @@
+package sql
+
+import (
+	"reflect"
+
+	"github.com/gopub/utils"
+)
+
+// ColumnDef describes one mapped struct field, in declaration order. It's
+// the information schema tooling (e.g. sql/migrate's auto-sync) needs
+// without reaching into Table's unexported column metadata.
+type ColumnDef struct {
+	Name       string
+	GoType     reflect.Type
+	PrimaryKey bool
+	AutoIncr   bool
+}
+
+// Columns returns the column definitions for typ, which must be a struct or
+// pointer to struct, in the same order Table uses for INSERT/SELECT.
+func Columns(typ reflect.Type) []ColumnDef {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	info := getColumnInfo(typ)
+	defs := make([]ColumnDef, 0, len(info.names))
+	for _, name := range info.names {
+		idx := info.nameToIndex[name]
+		defs = append(defs, ColumnDef{
+			Name:       name,
+			GoType:     typ.FieldByIndex(idx).Type,
+			PrimaryKey: utils.IndexOfString(info.pkNames, name) >= 0,
+			AutoIncr:   name == info.aiName,
+		})
+	}
+	return defs
+}
+
+// TableName returns the table name record would be stored under, following
+// the same tableNaming/pluralization rules as Table.
+func TableName(record interface{}) string {
+	return getTableName(record)
+}
@@ -1,12 +1,14 @@
 package sqlite
 
 import (
+	"context"
 	"fmt"
 	"github.com/golang/protobuf/proto"
 	"github.com/gopub/conv"
 	"github.com/gopub/log"
 	"github.com/gopub/sql"
 	"github.com/gopub/types"
+	"strings"
 	"sync"
 	"time"
 )
@@ -15,10 +17,21 @@ type Clock interface {
 	Now() time.Time
 }
 
+// kvState holds what a KVStore shares with every Bucket view derived from
+// it: they all operate on the same underlying table, so the mutex guarding
+// access and the janitor cleaning it up must be shared too, not copied.
+type kvState struct {
+	mu      sync.RWMutex
+	janitor *janitor
+}
+
+// KVStore is a sqlite-backed key/value store. Rows may carry an expiry,
+// and Bucket gives callers disjoint key spaces over the same table.
 type KVStore struct {
-	clock Clock
-	db    *sql.DB
-	mu    sync.RWMutex
+	clock  Clock
+	db     *sql.DB
+	prefix string
+	state  *kvState
 }
 
 func NewKVStore(filename string, clock Clock) *KVStore {
@@ -26,13 +39,15 @@ func NewKVStore(filename string, clock Clock) *KVStore {
 	r := &KVStore{
 		clock: clock,
 		db:    db,
+		state: &kvState{},
 	}
 
 	_, err := db.Exec(`
 CREATE TABLE IF NOT EXISTS kv(
-k VARCHAR(255) PRIMARY KEY, 
+k VARCHAR(255) PRIMARY KEY,
 v BLOB NOT NULL,
-updated_at BIGINT NOT NULL
+updated_at BIGINT NOT NULL,
+expires_at BIGINT NOT NULL DEFAULT 0
 )`)
 	if err != nil {
 		log.Fatalf("Create table: %v", err)
@@ -40,22 +55,45 @@ updated_at BIGINT NOT NULL
 	return r
 }
 
+// Bucket returns a namespaced view of the store: its keys live under
+// "name/" in the same underlying table, so callers can use disjoint key
+// spaces without colliding. Buckets can be nested by calling Bucket again.
+// The view shares r's state, not a fresh one, so Close/StartJanitor/
+// StopJanitor on either r or a bucket derived from it still serialize
+// against, and see, the other's in-flight calls and running janitor.
+func (r *KVStore) Bucket(name string) *KVStore {
+	return &KVStore{clock: r.clock, db: r.db, prefix: r.prefix + name + "/", state: r.state}
+}
+
+func (r *KVStore) fullKey(key string) string {
+	return r.prefix + key
+}
+
 func (r *KVStore) SaveInt64(key string, val int64) {
+	r.SaveInt64Context(context.Background(), key, val)
+}
+
+func (r *KVStore) SaveInt64Context(ctx context.Context, key string, val int64) {
 	logger := log.With("key", key)
-	r.mu.Lock()
-	_, err := r.db.Exec("REPLACE INTO kv(k,v,updated_at) VALUES(?1,?2,?3)",
-		key, fmt.Sprint(val), r.clock.Now())
-	r.mu.Unlock()
+	r.state.mu.Lock()
+	_, err := r.db.ExecContext(ctx, "REPLACE INTO kv(k,v,updated_at) VALUES(?1,?2,?3)",
+		r.fullKey(key), fmt.Sprint(val), r.clock.Now())
+	r.state.mu.Unlock()
 	if err != nil {
 		logger.Errorf("%v", err)
 	}
 }
 
 func (r *KVStore) GetInt64(key string) (int64, error) {
+	return r.GetInt64Context(context.Background(), key)
+}
+
+func (r *KVStore) GetInt64Context(ctx context.Context, key string) (int64, error) {
 	var v string
-	r.mu.RLock()
-	err := r.db.QueryRow("SELECT v FROM kv WHERE k=?", key).Scan(&v)
-	r.mu.RUnlock()
+	r.state.mu.RLock()
+	err := r.db.QueryRowContext(ctx, "SELECT v FROM kv WHERE k=? AND (expires_at=0 OR expires_at>?)",
+		r.fullKey(key), r.clock.Now().Unix()).Scan(&v)
+	r.state.mu.RUnlock()
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, types.ErrNotExist
@@ -71,20 +109,50 @@ func (r *KVStore) GetInt64(key string) (int64, error) {
 }
 
 func (r *KVStore) SaveData(key string, data []byte) {
+	r.SaveDataContext(context.Background(), key, data)
+}
+
+func (r *KVStore) SaveDataContext(ctx context.Context, key string, data []byte) {
 	logger := log.With("key", key)
-	r.mu.Lock()
-	_, err := r.db.Exec("REPLACE INTO kv(k,v,updated_at) VALUES(?1,?2,?3)", key, data, r.clock.Now())
-	r.mu.Unlock()
+	r.state.mu.Lock()
+	_, err := r.db.ExecContext(ctx, "REPLACE INTO kv(k,v,updated_at) VALUES(?1,?2,?3)", r.fullKey(key), data, r.clock.Now())
+	r.state.mu.Unlock()
+	if err != nil {
+		logger.Errorf("%v", err)
+	}
+}
+
+// SaveWithTTL is SaveData, except the row expires and starts returning
+// types.ErrNotExist after ttl elapses. ttl <= 0 means no expiry.
+func (r *KVStore) SaveWithTTL(key string, data []byte, ttl time.Duration) {
+	r.SaveWithTTLContext(context.Background(), key, data, ttl)
+}
+
+func (r *KVStore) SaveWithTTLContext(ctx context.Context, key string, data []byte, ttl time.Duration) {
+	logger := log.With("key", key)
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = r.clock.Now().Add(ttl).Unix()
+	}
+	r.state.mu.Lock()
+	_, err := r.db.ExecContext(ctx, "REPLACE INTO kv(k,v,updated_at,expires_at) VALUES(?1,?2,?3,?4)",
+		r.fullKey(key), data, r.clock.Now(), expiresAt)
+	r.state.mu.Unlock()
 	if err != nil {
 		logger.Errorf("%v", err)
 	}
 }
 
 func (r *KVStore) GetData(key string) ([]byte, error) {
+	return r.GetDataContext(context.Background(), key)
+}
+
+func (r *KVStore) GetDataContext(ctx context.Context, key string) ([]byte, error) {
 	var v []byte
-	r.mu.RLock()
-	err := r.db.QueryRow("SELECT v FROM kv WHERE k=?", key).Scan(&v)
-	r.mu.RUnlock()
+	r.state.mu.RLock()
+	err := r.db.QueryRowContext(ctx, "SELECT v FROM kv WHERE k=? AND (expires_at=0 OR expires_at>?)",
+		r.fullKey(key), r.clock.Now().Unix()).Scan(&v)
+	r.state.mu.RUnlock()
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, types.ErrNotExist
@@ -98,8 +166,16 @@ func (r *KVStore) SaveString(key string, s string) {
 	r.SaveData(key, []byte(s))
 }
 
+func (r *KVStore) SaveStringContext(ctx context.Context, key string, s string) {
+	r.SaveDataContext(ctx, key, []byte(s))
+}
+
 func (r *KVStore) GetString(key string) (string, error) {
-	data, err := r.GetData(key)
+	return r.GetStringContext(context.Background(), key)
+}
+
+func (r *KVStore) GetStringContext(ctx context.Context, key string) (string, error) {
+	data, err := r.GetDataContext(ctx, key)
 	if err != nil {
 		return "", err
 	}
@@ -107,25 +183,34 @@ func (r *KVStore) GetString(key string) (string, error) {
 }
 
 func (r *KVStore) SavePB(key string, msg proto.Message) {
+	r.SavePBContext(context.Background(), key, msg)
+}
+
+func (r *KVStore) SavePBContext(ctx context.Context, key string, msg proto.Message) {
 	logger := log.With("key", key)
 	data, err := proto.Marshal(msg)
 	if err != nil {
 		logger.Errorf("Marshal: %v", err)
 		return
 	}
-	r.mu.Lock()
-	_, err = r.db.Exec("REPLACE INTO kv(k,v,updated_at) VALUES(?1,?2,?3)", key, data, r.clock.Now())
-	r.mu.Unlock()
+	r.state.mu.Lock()
+	_, err = r.db.ExecContext(ctx, "REPLACE INTO kv(k,v,updated_at) VALUES(?1,?2,?3)", r.fullKey(key), data, r.clock.Now())
+	r.state.mu.Unlock()
 	if err != nil {
 		logger.Errorf("%v", err)
 	}
 }
 
 func (r *KVStore) GetPB(key string, msg proto.Message) error {
+	return r.GetPBContext(context.Background(), key, msg)
+}
+
+func (r *KVStore) GetPBContext(ctx context.Context, key string, msg proto.Message) error {
 	var v []byte
-	r.mu.RLock()
-	err := r.db.QueryRow("SELECT v FROM kv WHERE k=?", key).Scan(&v)
-	r.mu.RUnlock()
+	r.state.mu.RLock()
+	err := r.db.QueryRowContext(ctx, "SELECT v FROM kv WHERE k=? AND (expires_at=0 OR expires_at>?)",
+		r.fullKey(key), r.clock.Now().Unix()).Scan(&v)
+	r.state.mu.RUnlock()
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return types.ErrNotExist
@@ -136,28 +221,159 @@ func (r *KVStore) GetPB(key string, msg proto.Message) error {
 }
 
 func (r *KVStore) SaveJSON(key string, obj interface{}) {
+	r.SaveJSONContext(context.Background(), key, obj)
+}
+
+func (r *KVStore) SaveJSONContext(ctx context.Context, key string, obj interface{}) {
 	logger := log.With("key", key)
-	r.mu.Lock()
-	_, err := r.db.Exec("REPLACE INTO kv(k,v,updated_at) VALUES(?1,?2,?3)", key, sql.JSON(obj), r.clock.Now())
-	r.mu.Unlock()
+	r.state.mu.Lock()
+	_, err := r.db.ExecContext(ctx, "REPLACE INTO kv(k,v,updated_at) VALUES(?1,?2,?3)", r.fullKey(key), sql.JSON(obj), r.clock.Now())
+	r.state.mu.Unlock()
 	if err != nil {
 		logger.Errorf("%v", err)
 	}
 }
 
 func (r *KVStore) GetJSON(key string, ptrToObj interface{}) error {
-	r.mu.RLock()
-	err := r.db.QueryRow("SELECT v FROM kv WHERE k=?", key).Scan(sql.JSON(ptrToObj))
-	r.mu.RUnlock()
+	return r.GetJSONContext(context.Background(), key, ptrToObj)
+}
+
+func (r *KVStore) GetJSONContext(ctx context.Context, key string, ptrToObj interface{}) error {
+	r.state.mu.RLock()
+	err := r.db.QueryRowContext(ctx, "SELECT v FROM kv WHERE k=? AND (expires_at=0 OR expires_at>?)",
+		r.fullKey(key), r.clock.Now().Unix()).Scan(sql.JSON(ptrToObj))
+	r.state.mu.RUnlock()
 	if err == sql.ErrNoRows {
 		return types.ErrNotExist
 	}
 	return err
 }
 
+// Keys returns, in sorted order, the non-expired keys starting with
+// prefix. Keys are relative to the store's bucket, if any.
+func (r *KVStore) Keys(prefix string) ([]string, error) {
+	return r.KeysContext(context.Background(), prefix)
+}
+
+func (r *KVStore) KeysContext(ctx context.Context, prefix string) ([]string, error) {
+	r.state.mu.RLock()
+	defer r.state.mu.RUnlock()
+	rows, err := r.db.QueryContext(ctx, "SELECT k FROM kv WHERE k LIKE ? AND (expires_at=0 OR expires_at>?) ORDER BY k",
+		r.fullKey(prefix)+"%", r.clock.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			return nil, err
+		}
+		// LIKE is case-insensitive and treats % and _ as wildcards, so
+		// keep only keys that really start with the prefix.
+		if !strings.HasPrefix(k, r.fullKey(prefix)) {
+			continue
+		}
+		keys = append(keys, strings.TrimPrefix(k, r.prefix))
+	}
+	return keys, rows.Err()
+}
+
+// Range calls fn for every non-expired key starting with prefix, in sorted
+// order, stopping early if fn returns false. Keys passed to fn are
+// relative to the store's bucket, if any.
+func (r *KVStore) Range(prefix string, fn func(k string, v []byte) bool) error {
+	return r.RangeContext(context.Background(), prefix, fn)
+}
+
+func (r *KVStore) RangeContext(ctx context.Context, prefix string, fn func(k string, v []byte) bool) error {
+	r.state.mu.RLock()
+	defer r.state.mu.RUnlock()
+	rows, err := r.db.QueryContext(ctx, "SELECT k, v FROM kv WHERE k LIKE ? AND (expires_at=0 OR expires_at>?) ORDER BY k",
+		r.fullKey(prefix)+"%", r.clock.Now().Unix())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var k string
+		var v []byte
+		if err := rows.Scan(&k, &v); err != nil {
+			return err
+		}
+		if !strings.HasPrefix(k, r.fullKey(prefix)) {
+			continue
+		}
+		if !fn(strings.TrimPrefix(k, r.prefix), v) {
+			break
+		}
+	}
+	return rows.Err()
+}
+
+// janitor periodically clears expired rows in the background until
+// stopped.
+type janitor struct {
+	interval time.Duration
+	stop     chan struct{}
+}
+
+func (j *janitor) run(r *KVStore) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.deleteExpired()
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+func (r *KVStore) deleteExpired() {
+	r.state.mu.Lock()
+	_, err := r.db.Exec("DELETE FROM kv WHERE expires_at != 0 AND expires_at < ?", r.clock.Now().Unix())
+	r.state.mu.Unlock()
+	if err != nil {
+		log.Errorf("delete expired rows: %v", err)
+	}
+}
+
+// StartJanitor launches a background goroutine that deletes expired rows
+// every interval. Calling it again replaces any previously running
+// janitor. Close stops it automatically.
+func (r *KVStore) StartJanitor(interval time.Duration) {
+	j := &janitor{interval: interval, stop: make(chan struct{})}
+	r.state.mu.Lock()
+	old := r.state.janitor
+	r.state.janitor = j
+	r.state.mu.Unlock()
+	if old != nil {
+		close(old.stop)
+	}
+	go j.run(r)
+}
+
+// StopJanitor halts the background cleanup goroutine started by
+// StartJanitor, if any.
+func (r *KVStore) StopJanitor() {
+	r.state.mu.Lock()
+	j := r.state.janitor
+	r.state.janitor = nil
+	r.state.mu.Unlock()
+	if j != nil {
+		close(j.stop)
+	}
+}
+
 func (r *KVStore) Close() error {
-	r.mu.Lock()
+	r.StopJanitor()
+	r.state.mu.Lock()
 	err := r.db.Close()
-	r.mu.Unlock()
+	r.state.mu.Unlock()
 	return err
 }
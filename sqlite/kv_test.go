@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKVStoreBucketFullKey(t *testing.T) {
+	r := &KVStore{state: &kvState{}}
+	if got := r.fullKey("a"); got != "a" {
+		t.Errorf("root fullKey(a) = %q, want %q", got, "a")
+	}
+
+	b := r.Bucket("users")
+	if got := b.fullKey("1"); got != "users/1" {
+		t.Errorf("Bucket fullKey(1) = %q, want %q", got, "users/1")
+	}
+
+	nested := b.Bucket("sessions")
+	if got := nested.fullKey("abc"); got != "users/sessions/abc" {
+		t.Errorf("nested Bucket fullKey(abc) = %q, want %q", got, "users/sessions/abc")
+	}
+}
+
+func TestKVStoreBucketSharesState(t *testing.T) {
+	r := &KVStore{state: &kvState{}}
+	b := r.Bucket("users")
+	if b.state != r.state {
+		t.Error("Bucket should share its parent's state, not a fresh one, so Close/janitor serialize against and see it")
+	}
+
+	nested := b.Bucket("sessions")
+	if nested.state != r.state {
+		t.Error("nested Bucket should still share the root's state")
+	}
+}
+
+func TestKVStoreStartJanitorVisibleFromBucket(t *testing.T) {
+	r := &KVStore{state: &kvState{}}
+	r.StartJanitor(time.Hour)
+	defer r.StopJanitor()
+
+	b := r.Bucket("users")
+	if b.state.janitor == nil || b.state.janitor != r.state.janitor {
+		t.Error("a janitor started on the root should be visible through a Bucket view, not just the root")
+	}
+}
@@ -2,9 +2,9 @@ package sql
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"github.com/gopub/log"
 	"github.com/gopub/utils"
 	"github.com/jinzhu/inflection"
@@ -65,13 +65,33 @@ func isEmpty(jsonData []byte) bool {
 	return dataStr == "{}" || dataStr == "[]" || dataStr == "null" || dataStr == "NULL"
 }
 
+// Table maps a Go struct type onto a database table. Most operations
+// (Insert, Update, Save, Select, SelectOne, Delete, Count) have a
+// *Context sibling, e.g. InsertContext, that takes a context.Context and
+// threads it through to the underlying query for cancellation, deadlines,
+// and tracing; the plain form just calls it with context.Background().
 type Table struct {
 	exe        executor
 	driverName string
 	name       string
+	dialect    Dialect
+}
+
+// getDialect returns the Dialect for t.driverName, resolving and caching it
+// on first use.
+func (t *Table) getDialect() Dialect {
+	if t.dialect == nil {
+		t.dialect = dialectForDriver(t.driverName)
+	}
+	return t.dialect
 }
 
 func (t *Table) Insert(record interface{}) error {
+	return t.InsertContext(context.Background(), record)
+}
+
+func (t *Table) InsertContext(ctx context.Context, record interface{}) error {
+	dialect := t.getDialect()
 	query, values, err := t.prepareInsertQuery(record)
 	if err != nil {
 		log.Error(err)
@@ -81,15 +101,29 @@ func (t *Table) Insert(record interface{}) error {
 	if log.GetLevel() <= log.DebugLevel {
 		log.Debug(query, toReadableArgs(values))
 	}
-	result, err := t.exe.Exec(query, values...)
+
+	v := getStructValue(record)
+	info := getColumnInfo(v.Type())
+	needsAI := len(info.aiName) > 0 && v.FieldByIndex(info.nameToIndex[info.aiName]).Int() == 0
+
+	if needsAI && dialect.SupportsReturning() {
+		query += " RETURNING " + info.aiName
+		var id int64
+		if err := t.exe.QueryRowContext(ctx, query, values...).Scan(&id); err != nil {
+			log.Error(err)
+			return err
+		}
+		v.FieldByIndex(info.nameToIndex[info.aiName]).SetInt(id)
+		return nil
+	}
+
+	result, err := t.exe.ExecContext(ctx, query, values...)
 	if err != nil {
 		log.Error(err)
 		return err
 	}
-	v := getStructValue(record)
-	info := getColumnInfo(v.Type())
-	if len(info.aiName) > 0 && v.FieldByIndex(info.nameToIndex[info.aiName]).Int() == 0 {
-		id, err := result.LastInsertId()
+	if needsAI {
+		id, err := dialect.LastInsertID(result, info.aiName)
 		if err != nil {
 			log.Error(err)
 			return err
@@ -101,6 +135,7 @@ func (t *Table) Insert(record interface{}) error {
 }
 
 func (t *Table) prepareInsertQuery(record interface{}) (string, []interface{}, error) {
+	dialect := t.getDialect()
 	v := getStructValue(record)
 	info := getColumnInfo(v.Type())
 
@@ -122,17 +157,26 @@ func (t *Table) prepareInsertQuery(record interface{}) (string, []interface{}, e
 
 	var buf bytes.Buffer
 	buf.WriteString("INSERT INTO ")
-	buf.WriteString(t.name)
+	buf.WriteString(dialect.Quote(t.name))
 	buf.WriteString("(")
 	buf.WriteString(strings.Join(columns, ", "))
 	buf.WriteString(") VALUES (")
-	buf.WriteString(strings.Repeat("?, ", len(columns)))
-	buf.Truncate(buf.Len() - 2)
+	for i := range columns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(dialect.Placeholder(i + 1))
+	}
 	buf.WriteString(")")
 	return buf.String(), values, nil
 }
 
 func (t *Table) Update(record interface{}) error {
+	return t.UpdateContext(context.Background(), record)
+}
+
+func (t *Table) UpdateContext(ctx context.Context, record interface{}) error {
+	dialect := t.getDialect()
 	v := getStructValue(record)
 	info := getColumnInfo(v.Type())
 	if len(info.pkNames) == 0 {
@@ -141,14 +185,17 @@ func (t *Table) Update(record interface{}) error {
 
 	var buf bytes.Buffer
 	buf.WriteString("UPDATE ")
-	buf.WriteString(t.name)
+	buf.WriteString(dialect.Quote(t.name))
 	buf.WriteString(" SET ")
+	n := 0
 	for i, c := range info.notPKNames {
 		if i > 0 {
 			buf.WriteString(", ")
 		}
+		n++
 		buf.WriteString(c)
-		buf.WriteString(" = ?")
+		buf.WriteString(" = ")
+		buf.WriteString(dialect.Placeholder(n))
 	}
 
 	buf.WriteString(" WHERE ")
@@ -156,8 +203,10 @@ func (t *Table) Update(record interface{}) error {
 		if i > 0 {
 			buf.WriteString(" and ")
 		}
+		n++
 		buf.WriteString(c)
-		buf.WriteString(" = ?")
+		buf.WriteString(" = ")
+		buf.WriteString(dialect.Placeholder(n))
 	}
 
 	args := make([]interface{}, 0, len(info.indexes))
@@ -177,22 +226,29 @@ func (t *Table) Update(record interface{}) error {
 	if log.GetLevel() <= log.DebugLevel {
 		log.Debug(query, toReadableArgs(args))
 	}
-	_, err := t.exe.Exec(query, args...)
+	_, err := t.exe.ExecContext(ctx, query, args...)
 	return err
 }
 
 func (t *Table) Save(record interface{}) error {
+	return t.SaveContext(context.Background(), record)
+}
+
+func (t *Table) SaveContext(ctx context.Context, record interface{}) error {
 	switch t.driverName {
 	case "mysql":
-		return t.mysqlSave(record)
+		return t.mysqlSave(ctx, record)
 	case "sqlite3":
-		return t.sqliteSave(record)
+		return t.sqliteSave(ctx, record)
+	case "postgres":
+		return t.postgresSave(ctx, record)
 	default:
 		panic("Save operation is not supported for driver: " + t.driverName)
 	}
 }
 
-func (t *Table) mysqlSave(record interface{}) error {
+func (t *Table) mysqlSave(ctx context.Context, record interface{}) error {
+	dialect := t.getDialect()
 	query, values, err := t.prepareInsertQuery(record)
 	if err != nil {
 		log.Error(err)
@@ -202,31 +258,52 @@ func (t *Table) mysqlSave(record interface{}) error {
 	v := getStructValue(record)
 	info := getColumnInfo(v.Type())
 
-	var buf bytes.Buffer
-	buf.WriteString(query)
-	buf.WriteString(" ON DUPLICATE KEY UPDATE ")
-	for i, name := range info.names {
-		if i > 0 {
-			buf.WriteString(", ")
-		}
-		buf.WriteString(name)
-		buf.WriteString(" = ?")
-		fv, err := t.getFieldValueByName(v, info, name)
+	if len(info.pkNames) > 0 && len(info.notPKNames) > 0 {
+		// With no non-key columns, there is nothing to update on conflict;
+		// fall back to a plain insert, which fails safely on a duplicate key.
+		query += " " + dialect.UpsertClause(info.pkNames, info.notPKNames)
+	}
+
+	if log.GetLevel() <= log.DebugLevel {
+		log.Debug(query, toReadableArgs(values))
+	}
+
+	result, err := t.exe.ExecContext(ctx, query, values...)
+	if len(info.aiName) > 0 && v.FieldByIndex(info.nameToIndex[info.aiName]).Int() == 0 {
+		id, err := dialect.LastInsertID(result, info.aiName)
 		if err != nil {
+			log.Error(err)
 			return err
 		}
-		values = append(values, fv)
+		v.FieldByIndex(info.nameToIndex[info.aiName]).SetInt(id)
 	}
+	return err
+}
 
-	query = buf.String()
+func (t *Table) sqliteSave(ctx context.Context, record interface{}) error {
+	dialect := t.getDialect()
+	query, values, err := t.prepareInsertQuery(record)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	v := getStructValue(record)
+	info := getColumnInfo(v.Type())
+
+	if len(info.pkNames) > 0 && len(info.notPKNames) > 0 {
+		// With no non-key columns, there is nothing to update on conflict;
+		// fall back to a plain insert, which fails safely on a duplicate key.
+		query += " " + dialect.UpsertClause(info.pkNames, info.notPKNames)
+	}
 
 	if log.GetLevel() <= log.DebugLevel {
 		log.Debug(query, toReadableArgs(values))
 	}
 
-	result, err := t.exe.Exec(query, values...)
+	result, err := t.exe.ExecContext(ctx, query, values...)
 	if len(info.aiName) > 0 && v.FieldByIndex(info.nameToIndex[info.aiName]).Int() == 0 {
-		id, err := result.LastInsertId()
+		id, err := dialect.LastInsertID(result, info.aiName)
 		if err != nil {
 			log.Error(err)
 			return err
@@ -236,34 +313,57 @@ func (t *Table) mysqlSave(record interface{}) error {
 	return err
 }
 
-func (t *Table) sqliteSave(record interface{}) error {
+// postgresSave inserts record, upserting on the primary key via
+// ON CONFLICT ... DO UPDATE and RETURNING the auto-increment column, since
+// postgres has no LastInsertId equivalent.
+func (t *Table) postgresSave(ctx context.Context, record interface{}) error {
+	dialect := t.getDialect()
 	query, values, err := t.prepareInsertQuery(record)
 	if err != nil {
 		log.Error(err)
 		return err
 	}
 
-	query = strings.Replace(query, "INSERT INTO", "INSERT OR REPLACE INTO", 1)
 	v := getStructValue(record)
 	info := getColumnInfo(v.Type())
 
+	if len(info.pkNames) > 0 && len(info.notPKNames) > 0 {
+		// With no non-key columns, there is nothing to update on conflict;
+		// fall back to a plain insert, which fails safely on a duplicate key.
+		query += " " + dialect.UpsertClause(info.pkNames, info.notPKNames)
+	}
+
+	needsAI := len(info.aiName) > 0 && v.FieldByIndex(info.nameToIndex[info.aiName]).Int() == 0
+	if needsAI {
+		query += " RETURNING " + info.aiName
+	}
+
 	if log.GetLevel() <= log.DebugLevel {
 		log.Debug(query, toReadableArgs(values))
 	}
 
-	result, err := t.exe.Exec(query, values...)
-	if len(info.aiName) > 0 && v.FieldByIndex(info.nameToIndex[info.aiName]).Int() == 0 {
-		id, err := result.LastInsertId()
-		if err != nil {
+	if needsAI {
+		var id int64
+		if err := t.exe.QueryRowContext(ctx, query, values...).Scan(&id); err != nil {
 			log.Error(err)
 			return err
 		}
 		v.FieldByIndex(info.nameToIndex[info.aiName]).SetInt(id)
+		return nil
+	}
+
+	_, err = t.exe.ExecContext(ctx, query, values...)
+	if err != nil {
+		log.Error(err)
 	}
 	return err
 }
 
 func (t *Table) Select(records interface{}, where string, args ...interface{}) error {
+	return t.SelectContext(context.Background(), records, where, args...)
+}
+
+func (t *Table) SelectContext(ctx context.Context, records interface{}, where string, args ...interface{}) error {
 	v := reflect.ValueOf(records)
 	if v.Kind() != reflect.Ptr {
 		panic("must be a pointer to slice")
@@ -278,11 +378,9 @@ func (t *Table) Select(records interface{}, where string, args ...interface{}) e
 		panic("must be a pointer to slice")
 	}
 
-	isPtr := false
 	elemType := sliceType.Elem()
 	if elemType.Kind() == reflect.Ptr {
 		elemType = elemType.Elem()
-		isPtr = true
 	}
 
 	if elemType.Kind() != reflect.Struct {
@@ -290,119 +388,42 @@ func (t *Table) Select(records interface{}, where string, args ...interface{}) e
 	}
 
 	fi := getColumnInfo(elemType)
+	dialect := t.getDialect()
 
 	var buf bytes.Buffer
 	buf.WriteString("SELECT ")
 	buf.WriteString(strings.Join(fi.names, ", "))
 	buf.WriteString(" FROM ")
-	buf.WriteString(t.name)
+	buf.WriteString(dialect.Quote(t.name))
 	if len(where) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(where)
 	}
-	query := buf.String()
+	query := bindPlaceholders(dialect, buf.String())
 
 	if log.GetLevel() <= log.DebugLevel {
 		log.Debug(query, toReadableArgs(args))
 	}
 
-	rows, err := t.exe.Query(query, args...)
+	rows, err := t.exe.QueryContext(ctx, query, args...)
 	if err != nil {
 		log.Error(err)
 		return err
 	}
 	defer rows.Close()
 
-	if v.IsNil() {
-		v.Set(reflect.New(sliceType))
-	}
-	sliceValue := v.Elem()
-	fields := make([]interface{}, len(fi.indexes))
-	for rows.Next() {
-		ptrToElem := utils.DeepNew(elemType)
-		elem := ptrToElem.Elem()
-		for i, idx := range fi.indexes {
-			if utils.IndexOfString(fi.jsonNames, fi.names[i]) >= 0 {
-				var data []byte
-				fields[i] = &data
-			} else if utils.IndexOfString(fi.nullableNames, fi.names[i]) >= 0 {
-				switch elem.FieldByIndex(idx).Kind() {
-				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-					reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-					var v sql.NullInt64
-					fields[i] = &v
-				case reflect.Bool:
-					var b sql.NullBool
-					fields[i] = &b
-				case reflect.Float32, reflect.Float64:
-					var v sql.NullFloat64
-					fields[i] = &v
-				case reflect.String:
-					var v sql.NullString
-					fields[i] = &v
-				default:
-					panic("invalid nullable type" + fmt.Sprint(elem.FieldByIndex(idx).Type()))
-				}
-			} else {
-				fields[i] = elem.FieldByIndex(idx).Addr().Interface()
-			}
-		}
-
-		err = rows.Scan(fields...)
-		if err != nil {
-			log.Error(err)
-			return err
-		}
-
-		for _, name := range fi.jsonNames {
-			idx := fi.nameToIndex[name]
-			i := utils.IndexOfString(fi.names, name)
-			addr := fields[i]
-			data := reflect.ValueOf(addr).Elem().Interface()
-			err = json.Unmarshal(data.([]byte), elem.FieldByIndex(idx).Addr().Interface())
-			if err != nil {
-				log.Error(err)
-				return err
-			}
-		}
-
-		for _, name := range fi.nullableNames {
-			idx := fi.nameToIndex[name]
-			i := utils.IndexOfString(fi.names, name)
-			addr := fields[i]
-			switch v := reflect.ValueOf(addr).Elem().Interface().(type) {
-			case sql.NullString:
-				if v.Valid {
-					elem.FieldByIndex(idx).SetString(v.String)
-				}
-			case sql.NullFloat64:
-				if v.Valid {
-					elem.FieldByIndex(idx).SetFloat(v.Float64)
-				}
-			case sql.NullBool:
-				if v.Valid {
-					elem.FieldByIndex(idx).SetBool(v.Bool)
-				}
-			case sql.NullInt64:
-				if v.Valid {
-					elem.FieldByIndex(idx).SetInt(v.Int64)
-				}
-			default:
-				panic("invalid type:" + fmt.Sprint(v))
-			}
-		}
-
-		if isPtr {
-			sliceValue = reflect.Append(sliceValue, ptrToElem)
-		} else {
-			sliceValue = reflect.Append(sliceValue, elem)
-		}
+	if err := ScanRows(rows, records); err != nil {
+		log.Error(err)
+		return err
 	}
-	v.Elem().Set(sliceValue)
 	return nil
 }
 
 func (t *Table) SelectOne(record interface{}, where string, args ...interface{}) error {
+	return t.SelectOneContext(context.Background(), record, where, args...)
+}
+
+func (t *Table) SelectOneContext(ctx context.Context, record interface{}, where string, args ...interface{}) error {
 	rv := reflect.ValueOf(record)
 	if rv.Kind() != reflect.Ptr {
 		panic("not pointer to a struct")
@@ -420,99 +441,33 @@ func (t *Table) SelectOne(record interface{}, where string, args ...interface{})
 	}
 
 	info := getColumnInfo(elem.Type())
+	dialect := t.getDialect()
 
 	var buf bytes.Buffer
 	buf.WriteString("SELECT ")
 	buf.WriteString(strings.Join(info.names, ", "))
 	buf.WriteString(" FROM ")
-	buf.WriteString(t.name)
+	buf.WriteString(dialect.Quote(t.name))
 	if len(where) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(where)
 	}
-	query := buf.String()
+	query := bindPlaceholders(dialect, buf.String())
 
 	if log.GetLevel() <= log.DebugLevel {
 		log.Debug(query, toReadableArgs(args))
 	}
 
-	fieldAddrs := make([]interface{}, len(info.indexes))
-	for i, idx := range info.indexes {
-		if utils.IndexOfString(info.jsonNames, info.names[i]) >= 0 {
-			var data []byte
-			fieldAddrs[i] = &data
-		} else if utils.IndexOfString(info.nullableNames, info.names[i]) >= 0 {
-			switch elem.FieldByIndex(idx).Kind() {
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-				var v sql.NullInt64
-				fieldAddrs[i] = &v
-			case reflect.Bool:
-				var b sql.NullBool
-				fieldAddrs[i] = &b
-			case reflect.Float32, reflect.Float64:
-				var v sql.NullFloat64
-				fieldAddrs[i] = &v
-			case reflect.String:
-				var v sql.NullString
-				fieldAddrs[i] = &v
-			default:
-				panic("invalid nullable type" + fmt.Sprint(elem.FieldByIndex(idx).Type()))
-			}
-		} else {
-			fieldAddrs[i] = elem.FieldByIndex(idx).Addr().Interface()
-		}
-	}
-	err := t.exe.QueryRow(query, args...).Scan(fieldAddrs...)
-	if err != nil {
+	row := t.exe.QueryRowContext(ctx, query, args...)
+	if err := ScanRow(row, elem.Addr().Interface()); err != nil {
 		if err != sql.ErrNoRows {
 			log.Error(err)
 		}
 		return err
 	}
 
-	for _, name := range info.jsonNames {
-		idx := info.nameToIndex[name]
-		i := utils.IndexOfString(info.names, name)
-		addr := fieldAddrs[i]
-		data := reflect.ValueOf(addr).Elem().Interface()
-		err = json.Unmarshal(data.([]byte), elem.FieldByIndex(idx).Addr().Interface())
-		if err != nil {
-			log.Error(err)
-			return err
-		}
-	}
-
-	for _, name := range info.nullableNames {
-		idx := info.nameToIndex[name]
-		i := utils.IndexOfString(info.names, name)
-		addr := fieldAddrs[i]
-		switch v := reflect.ValueOf(addr).Elem().Interface().(type) {
-		case sql.NullString:
-			if v.Valid {
-				elem.FieldByIndex(idx).SetString(v.String)
-			}
-		case sql.NullFloat64:
-			if v.Valid {
-				elem.FieldByIndex(idx).SetFloat(v.Float64)
-			}
-		case sql.NullBool:
-			if v.Valid {
-				elem.FieldByIndex(idx).SetBool(v.Bool)
-			}
-		case sql.NullInt64:
-			if v.Valid {
-				elem.FieldByIndex(idx).SetInt(v.Int64)
-			}
-		default:
-			panic("invalid type:" + fmt.Sprint(v))
-		}
-	}
-
-	if err == nil {
-		rv.Elem().Set(ev)
-	}
-	return err
+	rv.Elem().Set(ev)
+	return nil
 }
 
 /*
@@ -527,22 +482,27 @@ func (t *Table) Query(query string, args ...interface{}) (*Rows, error) {
 }*/
 
 func (t *Table) Delete(where string, args ...interface{}) error {
+	return t.DeleteContext(context.Background(), where, args...)
+}
+
+func (t *Table) DeleteContext(ctx context.Context, where string, args ...interface{}) error {
 	if len(where) == 0 {
 		panic("where is empty")
 	}
+	dialect := t.getDialect()
 	var buf bytes.Buffer
 	buf.WriteString("DELETE FROM ")
-	buf.WriteString(t.name)
+	buf.WriteString(dialect.Quote(t.name))
 	buf.WriteString(" WHERE ")
 	buf.WriteString(where)
 
-	query := buf.String()
+	query := bindPlaceholders(dialect, buf.String())
 
 	if log.GetLevel() <= log.DebugLevel {
 		log.Debug(query, toReadableArgs(args))
 	}
 
-	_, err := t.exe.Exec(query, args...)
+	_, err := t.exe.ExecContext(ctx, query, args...)
 	if err != nil {
 		log.Error(err)
 	}
@@ -550,21 +510,26 @@ func (t *Table) Delete(where string, args ...interface{}) error {
 }
 
 func (t *Table) Count(where string, args ...interface{}) (int, error) {
+	return t.CountContext(context.Background(), where, args...)
+}
+
+func (t *Table) CountContext(ctx context.Context, where string, args ...interface{}) (int, error) {
+	dialect := t.getDialect()
 	var buf bytes.Buffer
 	buf.WriteString("SELECT COUNT(*) FROM ")
-	buf.WriteString(t.name)
+	buf.WriteString(dialect.Quote(t.name))
 	if len(where) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(where)
 	}
-	query := buf.String()
+	query := bindPlaceholders(dialect, buf.String())
 
 	if log.GetLevel() <= log.DebugLevel {
 		log.Debug(query, toReadableArgs(args))
 	}
 
 	var count int
-	err := t.exe.QueryRow(query, args...).Scan(&count)
+	err := t.exe.QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		log.Error(err)
 		return 0, err